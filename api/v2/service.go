@@ -0,0 +1,110 @@
+// Package apiv2 wires the v2 gRPC service implementations and the
+// grpc-gateway REST mux that exposes them over HTTP alongside api/v1.
+package apiv2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/yourselfhosted/slash/server/profile"
+	"github.com/yourselfhosted/slash/server/service/grpcmw"
+	"github.com/yourselfhosted/slash/server/service/license"
+	"github.com/yourselfhosted/slash/store"
+)
+
+// APIV2Service wires the v2 gRPC service implementations and the
+// grpc-gateway REST mux that exposes them over HTTP alongside api/v1.
+type APIV2Service struct {
+	secret         string
+	profile        *profile.Profile
+	store          *store.Store
+	licenseService *license.LicenseService
+	grpcPort       int
+
+	grpcServer *grpc.Server
+}
+
+// NewAPIV2Service constructs the v2 gRPC server, chaining the request-ID
+// logging and RED metrics interceptors from grpcmw so every RPC is
+// correlated with the HTTP request that triggered it and shows up in the
+// same slash_grpc_* series the HTTP side's httpMetricsMiddleware mirrors.
+func NewAPIV2Service(secret string, profile *profile.Profile, store *store.Store, licenseService *license.LicenseService, grpcPort int) *APIV2Service {
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcmw.UnaryServerInterceptor(), grpcmw.MetricsUnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(grpcmw.StreamServerInterceptor(), grpcmw.MetricsStreamServerInterceptor()),
+	)
+
+	return &APIV2Service{
+		secret:         secret,
+		profile:        profile,
+		store:          store,
+		licenseService: licenseService,
+		grpcPort:       grpcPort,
+		grpcServer:     grpcServer,
+	}
+}
+
+// GetGRPCServer returns the underlying grpc.Server so the caller can Serve
+// it and, on shutdown, GracefulStop it.
+func (s *APIV2Service) GetGRPCServer() *grpc.Server {
+	return s.grpcServer
+}
+
+// gatewayHandlers is populated by each v2 service's generated package via
+// RegisterGatewayHandler, so RegisterGateway stays agnostic of which
+// services exist.
+var gatewayHandlers []func(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error
+
+// RegisterGatewayHandler lets a v2 service register its grpc-gateway REST
+// handler without RegisterGateway needing to know about it directly.
+func RegisterGatewayHandler(register func(ctx context.Context, mux *runtime.ServeMux, conn *grpc.ClientConn) error) {
+	gatewayHandlers = append(gatewayHandlers, register)
+}
+
+// RegisterGateway mounts a grpc-gateway REST mux on e, translating HTTP
+// requests under /slash.api.v2.* into calls against the local gRPC server.
+func (s *APIV2Service) RegisterGateway(ctx context.Context, e *echo.Echo) error {
+	conn, err := grpc.DialContext(ctx, fmt.Sprintf("localhost:%d", s.grpcPort),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to dial grpc server")
+	}
+
+	mux := runtime.NewServeMux(runtime.WithMetadata(requestIDMetadataAnnotator))
+	for _, register := range gatewayHandlers {
+		if err := register(ctx, mux, conn); err != nil {
+			return errors.Wrap(err, "failed to register gateway handler")
+		}
+	}
+
+	e.Any("/slash.api.v2.*", echo.WrapHandler(mux))
+	return nil
+}
+
+// requestIDMetadataAnnotator carries the request ID that
+// server.contextWithRequestID already appended to ctx's outgoing gRPC
+// metadata across grpc-gateway's own runtime.AnnotateContext call. That call
+// always finds at least one pair (it unconditionally adds x-forwarded-host),
+// so it replaces rather than merges the outgoing metadata already on ctx,
+// silently dropping the request ID. Metadata returned here is instead
+// combined via metadata.Join, so it survives.
+func requestIDMetadataAnnotator(ctx context.Context, _ *http.Request) metadata.MD {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		return nil
+	}
+	ids := md.Get(grpcmw.RequestIDMetadataKey)
+	if len(ids) == 0 {
+		return nil
+	}
+	return metadata.Pairs(grpcmw.RequestIDMetadataKey, ids[0])
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/soheilhy/cmux"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/yourselfhosted/slash/internal/log"
+	"github.com/yourselfhosted/slash/server/metric"
+)
+
+// Listeners holds the listeners produced by Server.Listen, split out of the
+// shared multiplexed socket so Run can hand each protocol its own net.Listener.
+type Listeners struct {
+	mux  cmux.CMux
+	grpc net.Listener
+	http net.Listener
+}
+
+// Listen binds the configured profile port and multiplexes it into a gRPC
+// listener and an HTTP (Echo) listener, matching gRPC requests by their
+// "application/grpc" Content-Type / HTTP/2 preface and falling back to HTTP
+// for everything else. It is split from Run so tests can inject an
+// in-memory listener via listen below instead of binding a real socket.
+func (s *Server) Listen() (*Listeners, error) {
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", s.Profile.Port))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to listen")
+	}
+	return s.listen(l)
+}
+
+// listen wraps an already-bound listener with the cmux multiplexer. Exposed
+// separately from Listen so tests can pass a bufconn-style in-memory
+// listener without binding a real TCP port.
+func (s *Server) listen(l net.Listener) (*Listeners, error) {
+	m := cmux.New(l)
+	grpcL := m.MatchWithWriters(
+		cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"),
+	)
+	httpL := m.Match(cmux.HTTP1Fast(), cmux.HTTP2())
+	return &Listeners{mux: m, grpc: grpcL, http: httpL}, nil
+}
+
+// Run serves the gRPC and Echo servers on the listeners returned by Listen
+// and blocks until both servers and the mux on the shared socket have
+// returned, which happens once Shutdown closes them. ctx being done does not
+// stop serving, and an error from one server is returned only after the
+// others have stopped too. Keeping Listen and Run separate means the bound
+// socket survives a config reload: ReloadConfig swaps settings into the live
+// middleware without touching the Listeners.
+func (s *Server) Run(ctx context.Context, lns *Listeners) error {
+	s.lns = lns
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		if err := s.apiV2Service.GetGRPCServer().Serve(lns.grpc); err != nil && !isClosedErr(err) {
+			return errors.Wrap(err, "failed to serve grpc")
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		s.e.Listener = lns.http
+		if err := s.e.Start(""); err != nil && !isClosedErr(err) {
+			return errors.Wrap(err, "failed to serve http")
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		if err := lns.mux.Serve(); err != nil && !isClosedErr(err) {
+			return errors.Wrap(err, "failed to serve mux")
+		}
+		return nil
+	})
+
+	s.trackBackground(s.refreshLicenseSubscription)
+	s.trackBackground(s.watchReloadSignal)
+	s.trackBackground(s.watchRotateSignal)
+	s.trackBackground(s.sampleGaugesUntil)
+	metric.Enqueue("server start")
+
+	return g.Wait()
+}
+
+// watchReloadSignal calls ReloadConfig whenever the process receives
+// SIGHUP, the conventional "reread your config" signal, so operators can
+// push workspace settings changes (secret, CORS, rate limit, basic auth)
+// without dropping the socket Listen already bound.
+func (s *Server) watchReloadSignal(ctx context.Context) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	defer signal.Stop(sigCh)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigCh:
+			if err := s.ReloadConfig(ctx); err != nil {
+				log.Error("failed to reload config", zap.Error(err))
+			}
+		}
+	}
+}
+
+// watchRotateSignal calls RotateKeyset whenever the process receives
+// SIGUSR1, giving operators a way to retire the current cookie-signing key
+// without a restart, the same way SIGHUP reloads the rest of the config.
+func (s *Server) watchRotateSignal(ctx context.Context) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGUSR1)
+	defer signal.Stop(sigCh)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigCh:
+			if err := s.RotateKeyset(ctx); err != nil {
+				log.Error("failed to rotate keyset", zap.Error(err))
+			}
+		}
+	}
+}
+
+// refreshLicenseSubscription loads the license subscription on startup and
+// keeps it current for the life of the server, so a lapsed or upgraded
+// license is picked up without a restart. It runs under backgroundCtx and
+// is tracked by s.bg, so Shutdown waits for it to exit before closing the
+// store.
+func (s *Server) refreshLicenseSubscription(ctx context.Context) {
+	const refreshInterval = time.Hour
+
+	load := func() {
+		if _, err := s.licenseService.LoadSubscription(ctx); err != nil {
+			log.Error("failed to load subscription", zap.Error(err))
+		}
+	}
+	load()
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			load()
+		}
+	}
+}
+
+func isClosedErr(err error) bool {
+	return errors.Is(err, net.ErrClosed) || errors.Is(err, cmux.ErrListenerClosed) || errors.Is(err, cmux.ErrServerClosed)
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+func TestServerListen(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	defer l.Close()
+
+	var s *Server
+	lns, err := s.listen(l)
+	if err != nil {
+		t.Fatalf("listen() error = %v", err)
+	}
+	if lns.grpc == nil {
+		t.Error("lns.grpc is nil")
+	}
+	if lns.http == nil {
+		t.Error("lns.http is nil")
+	}
+}
+
+// TestServerListenRoutesConnectionsByProtocol exercises the actual cmux
+// dispatch listen sets up: a plain HTTP/1.1 request must be routed to
+// lns.http, while an HTTP/2 request carrying the "application/grpc"
+// content-type gRPC uses must be routed to lns.grpc.
+func TestServerListenRoutesConnectionsByProtocol(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	addr := l.Addr().String()
+
+	var s *Server
+	lns, err := s.listen(l)
+	if err != nil {
+		t.Fatalf("listen() error = %v", err)
+	}
+
+	go lns.mux.Serve()
+	defer lns.mux.Close()
+
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	go http.Serve(lns.http, okHandler)
+	go func() {
+		for {
+			conn, err := lns.grpc.Accept()
+			if err != nil {
+				return
+			}
+			go (&http2.Server{}).ServeConn(conn, &http2.ServeConnOpts{Handler: okHandler})
+		}
+	}()
+
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	resp, err := httpClient.Get("http://" + addr)
+	if err != nil {
+		t.Fatalf("HTTP/1.1 request error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("HTTP/1.1 request status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	grpcClient := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+	req, err := http.NewRequest(http.MethodPost, "http://"+addr, nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest() error = %v", err)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	resp, err = grpcClient.Do(req)
+	if err != nil {
+		t.Fatalf("gRPC-style request error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("gRPC-style request status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/yourselfhosted/slash/store"
+)
+
+// buildCommit is stamped at build time via -ldflags, mirroring how Version
+// is threaded through profile.Profile.
+var buildCommit = "unknown"
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "slash_http_requests_total",
+		Help: "Total number of HTTP requests, by method, route, and status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "slash_http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds, by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	dbConnectionsActive = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "slash_db_connections_active",
+		Help: "Number of open database connections.",
+	})
+
+	shortcutsTotal = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "slash_shortcuts_total",
+		Help: "Total number of shortcuts stored in the workspace.",
+	})
+
+	licenseSeatsUsed = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "slash_license_seats_used",
+		Help: "Number of license seats currently in use.",
+	})
+
+	buildInfo = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "slash_build_info",
+		Help: "Always 1, labelled with the running version/commit.",
+	}, []string{"version", "commit"})
+)
+
+// registerMetricsRoute mounts /metrics on guardedGroup, so it sits behind the
+// same basic auth setting as /healthz and the resource service. The gauge
+// sampler it feeds is started separately, from Run in listener.go alongside
+// the server's other background workers, so constructing a Server via
+// NewServer without calling Run doesn't leak a goroutine polling the DB.
+func (s *Server) registerMetricsRoute(guardedGroup *echo.Group) {
+	buildInfo.WithLabelValues(s.Profile.Version, buildCommit).Set(1)
+	guardedGroup.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
+}
+
+// httpMetricsMiddleware records RED metrics for every HTTP request. It's
+// kept separate from requestLoggerMiddleware so a Prometheus scrape outage
+// can't be confused with a logging regression.
+func (s *Server) httpMetricsMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			route := c.Path()
+			method := c.Request().Method
+			httpRequestsTotal.WithLabelValues(method, route, strconv.Itoa(c.Response().Status)).Inc()
+			httpRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
+
+// sampleGaugesUntil periodically refreshes the gauges that reflect current
+// state rather than a single request: active DB connections, shortcut
+// count, and license seat usage.
+func (s *Server) sampleGaugesUntil(ctx context.Context) {
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sampleGauges(ctx)
+		}
+	}
+}
+
+func (s *Server) sampleGauges(ctx context.Context) {
+	dbConnectionsActive.Set(float64(s.Store.GetDB().Stats().OpenConnections))
+
+	shortcuts, err := s.Store.ListShortcuts(ctx, &store.FindShortcut{})
+	if err == nil {
+		shortcutsTotal.Set(float64(len(shortcuts)))
+	}
+
+	// Routed through licenseService rather than read straight off the store
+	// row, so the gauge reflects whatever validation/derivation the service
+	// applies on top of it instead of silently diverging from what it
+	// considers authoritative.
+	if seatsUsed, err := s.licenseService.SeatsUsed(ctx); err == nil {
+		licenseSeatsUsed.Set(float64(seatsUsed))
+	}
+}
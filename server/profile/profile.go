@@ -0,0 +1,25 @@
+// Package profile holds the server-wide configuration resolved from
+// flags/env at startup and threaded through server.Server and
+// apiv2.APIV2Service.
+package profile
+
+// Profile is the runtime configuration shared across the server and its
+// services.
+type Profile struct {
+	// Mode is the runtime mode, e.g. "prod" or "dev".
+	Mode string
+
+	// Port is the TCP port the server listens on for both HTTP and gRPC,
+	// multiplexed over a single socket via cmux.
+	Port int
+
+	// Version is the running build's semantic version, surfaced in
+	// slash_build_info and the frontend.
+	Version string
+
+	// KeysetPath is where the AES-GCM keyset used to seal workspace secrets
+	// (currently the cookie-signing session secret) at rest is persisted.
+	// Required when Mode is "prod": server.Server refuses to start rather
+	// than bootstrap a keyset it can't persist anywhere.
+	KeysetPath string
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/yourselfhosted/slash/server/service/grpcmw"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// requestIDFromHeader returns the client-supplied X-Request-ID, generating a
+// fresh one via uuid.NewString() when the header is empty.
+func requestIDFromHeader(header string) string {
+	if header != "" {
+		return header
+	}
+	return uuid.NewString()
+}
+
+// contextWithRequestID appends id to ctx as outgoing gRPC metadata under
+// grpcmw.RequestIDMetadataKey, so the request ID set by the HTTP logger
+// rides along into the gateway call and comes back out as incoming metadata
+// for grpcmw.UnaryServerInterceptor/StreamServerInterceptor on the apiv2
+// side. apiv2.RegisterGateway's metadata annotator re-reads it from here,
+// since grpc-gateway's own AnnotateContext otherwise overwrites it.
+func contextWithRequestID(ctx context.Context, id string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, grpcmw.RequestIDMetadataKey, id)
+}
+
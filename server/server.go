@@ -2,11 +2,14 @@ package server
 
 import (
 	"context"
-	"fmt"
+	"crypto/sha256"
+	"crypto/subtle"
+	stderrors "errors"
 	"log/slog"
-	"net"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,18 +17,38 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	timerate "golang.org/x/time/rate"
 
 	apiv1 "github.com/yourselfhosted/slash/api/v1"
 	apiv2 "github.com/yourselfhosted/slash/api/v2"
 	"github.com/yourselfhosted/slash/internal/log"
 	storepb "github.com/yourselfhosted/slash/proto/gen/store"
-	"github.com/yourselfhosted/slash/server/metric"
 	"github.com/yourselfhosted/slash/server/profile"
+	"github.com/yourselfhosted/slash/server/service/keyset"
 	"github.com/yourselfhosted/slash/server/service/license"
 	"github.com/yourselfhosted/slash/server/service/resource"
 	"github.com/yourselfhosted/slash/store"
 )
 
+// dynamicConfig holds the subset of settings that NewServer wires into
+// middleware closures so ReloadConfig can swap them in place, without
+// dropping the listener set up by Listen.
+type dynamicConfig struct {
+	corsOrigins []string
+	rateLimit   middleware.RateLimiterMemoryStoreConfig
+	basicAuth   *basicAuthConfig
+}
+
+// basicAuthConfig holds the SHA-256 digests checked by basicAuthMiddleware,
+// so the plaintext credentials from the workspace setting are only ever
+// compared, never retained in memory for longer than a reload cycle.
+type basicAuthConfig struct {
+	enabled        bool
+	usernameDigest [sha256.Size]byte
+	passwordDigest [sha256.Size]byte
+}
+
 type Server struct {
 	e *echo.Echo
 
@@ -37,6 +60,32 @@ type Server struct {
 
 	// API services.
 	apiV2Service *apiv2.APIV2Service
+
+	config atomic.Pointer[dynamicConfig]
+
+	// lns is the cmux-multiplexed socket Run is serving, set by Run so
+	// Shutdown can close the root listener alongside the HTTP/gRPC
+	// sub-listeners it splits into.
+	lns *Listeners
+
+	// backgroundCtx is cancelled by Shutdown, once the HTTP and gRPC servers
+	// have drained and the root listener is closed, so long-running
+	// workers (license subscription refresh, metrics sampling) know to
+	// stop; bg tracks them so Shutdown can wait for them to exit before
+	// closing the store out from under them.
+	backgroundCtx    context.Context
+	cancelBackground context.CancelFunc
+	bg               sync.WaitGroup
+}
+
+// trackBackground runs fn in a goroutine tracked by s.bg, passing it
+// s.backgroundCtx so it can exit promptly once Shutdown cancels it.
+func (s *Server) trackBackground(fn func(ctx context.Context)) {
+	s.bg.Add(1)
+	go func() {
+		defer s.bg.Done()
+		fn(s.backgroundCtx)
+	}()
 }
 
 func NewServer(ctx context.Context, profile *profile.Profile, store *store.Store) (*Server, error) {
@@ -47,22 +96,41 @@ func NewServer(ctx context.Context, profile *profile.Profile, store *store.Store
 
 	licenseService := license.NewLicenseService(profile, store)
 
+	backgroundCtx, cancelBackground := context.WithCancel(context.Background())
 	s := &Server{
-		e:              e,
-		Profile:        profile,
-		Store:          store,
-		licenseService: licenseService,
+		e:                e,
+		Profile:          profile,
+		Store:            store,
+		licenseService:   licenseService,
+		backgroundCtx:    backgroundCtx,
+		cancelBackground: cancelBackground,
+	}
+	basicAuth, err := s.loadBasicAuthConfig(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load basic auth setting")
 	}
+	s.config.Store(&dynamicConfig{
+		corsOrigins: []string{"*"},
+		rateLimit:   middleware.RateLimiterMemoryStoreConfig{Rate: 30, Burst: 60, ExpiresIn: 3 * time.Minute},
+		basicAuth:   basicAuth,
+	})
 
-	e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: `{"time":"${time_rfc3339}",` +
-			`"method":"${method}","uri":"${uri}",` +
-			`"status":${status},"error":"${error}"}` + "\n",
-	}))
+	e.Use(s.requestLoggerMiddleware())
+	e.Use(s.httpMetricsMiddleware())
 
+	// CORS and rate-limit read the live config on every request so
+	// ReloadConfig can change workspace settings without rebuilding the
+	// middleware chain or dropping the listener.
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		Skipper:      grpcRequestSkipper,
-		AllowOrigins: []string{"*"},
+		Skipper: grpcRequestSkipper,
+		AllowOriginFunc: func(origin string) (bool, error) {
+			for _, allowed := range s.config.Load().corsOrigins {
+				if allowed == "*" || allowed == origin {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
 		AllowMethods: []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
 	}))
 
@@ -73,9 +141,7 @@ func NewServer(ctx context.Context, profile *profile.Profile, store *store.Store
 
 	e.Use(middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
 		Skipper: grpcRequestSkipper,
-		Store: middleware.NewRateLimiterMemoryStoreWithConfig(
-			middleware.RateLimiterMemoryStoreConfig{Rate: 30, Burst: 60, ExpiresIn: 3 * time.Minute},
-		),
+		Store:   &reloadableRateLimiterStore{s: s},
 		IdentifierExtractor: func(ctx echo.Context) (string, error) {
 			id := ctx.RealIP()
 			return id, nil
@@ -103,17 +169,25 @@ func NewServer(ctx context.Context, profile *profile.Profile, store *store.Store
 	}
 	s.Secret = secret
 
-	// Register healthz endpoint.
-	e.GET("/healthz", func(c echo.Context) error {
+	// /healthz and the resource service are non-API surfaces that can carry
+	// sensitive data (DB health, resource blobs), so they sit behind the
+	// optional basic auth guard, unlike the already-authenticated api v1
+	// routes on rootGroup and the api v2 gateway registered on e.
+	guardedGroup := e.Group("", s.basicAuthMiddleware())
+	guardedGroup.GET("/healthz", func(c echo.Context) error {
 		return c.String(http.StatusOK, "Service ready.")
 	})
+	s.registerMetricsRoute(guardedGroup)
 
 	rootGroup := e.Group("")
 	// Register API v1 routes.
 	apiV1Service := apiv1.NewAPIV1Service(profile, store, licenseService)
 	apiV1Service.Start(rootGroup, secret)
 
-	s.apiV2Service = apiv2.NewAPIV2Service(secret, profile, store, licenseService, s.Profile.Port+1)
+	// gRPC and HTTP now share a single port via the cmux multiplexer set up
+	// in Listen, so the gateway dials back to the same port instead of a
+	// dedicated Port+1 gRPC side-port.
+	s.apiV2Service = apiv2.NewAPIV2Service(secret, profile, store, licenseService, s.Profile.Port)
 	// Register gRPC gateway as api v2.
 	if err := s.apiV2Service.RegisterGateway(ctx, e); err != nil {
 		return nil, errors.Wrap(err, "failed to register gRPC gateway")
@@ -121,46 +195,183 @@ func NewServer(ctx context.Context, profile *profile.Profile, store *store.Store
 
 	// Register resource service.
 	resourceService := resource.NewResourceService(profile, store)
-	resourceService.Register(rootGroup)
+	resourceService.Register(guardedGroup)
 
 	return s, nil
 }
 
+// Start binds the profile port and serves until Shutdown stops the servers
+// (see Run for how ctx and errors are handled). It's a thin convenience wrapper around Listen+Run for callers that
+// don't need to hold onto the Listeners themselves (e.g. to reload config
+// without dropping the socket).
 func (s *Server) Start(ctx context.Context) error {
-	// Load subscription.
-	if _, err := s.licenseService.LoadSubscription(ctx); err != nil {
-		log.Error("failed to load subscription", zap.Error(err))
-	}
-	// Start gRPC server.
-	listen, err := net.Listen("tcp", fmt.Sprintf(":%d", s.Profile.Port+1))
+	lns, err := s.Listen()
 	if err != nil {
 		return err
 	}
-	go func() {
-		if err := s.apiV2Service.GetGRPCServer().Serve(listen); err != nil {
-			slog.Log(ctx, slog.LevelError, "failed to start grpc server")
+	return s.Run(ctx, lns)
+}
+
+// ReloadConfig re-reads the workspace secret (in prod mode only), CORS
+// origins, rate-limit rules and basic auth setting from the store. CORS,
+// rate-limit and basic auth are swapped into the live middleware chain. The
+// secret is assigned to s.Secret only: the api v1 and v2 services keep the
+// secret they were constructed with in NewServer. The bound socket and in-flight connections are untouched, so operators can
+// push settings changes without a restart. It is triggered by SIGHUP; see
+// watchReloadSignal in listener.go.
+func (s *Server) ReloadConfig(ctx context.Context) error {
+	// Mirrors NewServer: dev mode keeps the const "slash" secret for
+	// session persistence across restarts, so reloading it from the
+	// keyset-backed store here would invalidate every dev session instead
+	// of preserving them, and would fail outright once profile.KeysetPath
+	// isn't set (the normal dev case).
+	if s.Profile.Mode == "prod" {
+		secret, err := s.getSecretSessionName(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to reload secret session")
 		}
-	}()
+		s.Secret = secret
+	}
 
-	metric.Enqueue("server start")
-	return s.e.Start(fmt.Sprintf(":%d", s.Profile.Port))
+	corsSetting, err := s.Store.GetWorkspaceSetting(ctx, &store.FindWorkspaceSetting{
+		Key: storepb.WorkspaceSettingKey_WORKSPACE_SETTING_CORS_ORIGINS,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to reload cors origins")
+	}
+	corsOrigins := []string{"*"}
+	if corsSetting != nil && len(corsSetting.GetCorsOrigins().GetOrigins()) > 0 {
+		corsOrigins = corsSetting.GetCorsOrigins().GetOrigins()
+	}
+
+	rateLimitSetting, err := s.Store.GetWorkspaceSetting(ctx, &store.FindWorkspaceSetting{
+		Key: storepb.WorkspaceSettingKey_WORKSPACE_SETTING_RATE_LIMIT,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to reload rate limit")
+	}
+	rateLimit := middleware.RateLimiterMemoryStoreConfig{Rate: 30, Burst: 60, ExpiresIn: 3 * time.Minute}
+	if rateLimitSetting != nil {
+		if rate := rateLimitSetting.GetRateLimit().GetRate(); rate > 0 {
+			rateLimit.Rate = timerate.Limit(rate)
+		}
+		if burst := rateLimitSetting.GetRateLimit().GetBurst(); burst > 0 {
+			rateLimit.Burst = burst
+		}
+	}
+
+	basicAuth, err := s.loadBasicAuthConfig(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to reload basic auth")
+	}
+
+	s.config.Store(&dynamicConfig{corsOrigins: corsOrigins, rateLimit: rateLimit, basicAuth: basicAuth})
+	return nil
+}
+
+// loadBasicAuthConfig reads the WORKSPACE_SETTING_BASIC_AUTH setting and
+// reduces the configured credentials to their SHA-256 digests up front, so
+// basicAuthMiddleware only ever holds digests, never plaintext.
+func (s *Server) loadBasicAuthConfig(ctx context.Context) (*basicAuthConfig, error) {
+	setting, err := s.Store.GetWorkspaceSetting(ctx, &store.FindWorkspaceSetting{
+		Key: storepb.WorkspaceSettingKey_WORKSPACE_SETTING_BASIC_AUTH,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if setting == nil || !setting.GetBasicAuth().GetEnabled() {
+		return &basicAuthConfig{}, nil
+	}
+	return &basicAuthConfig{
+		enabled:        true,
+		usernameDigest: sha256.Sum256([]byte(setting.GetBasicAuth().GetUsername())),
+		passwordDigest: sha256.Sum256([]byte(setting.GetBasicAuth().GetPassword())),
+	}, nil
+}
+
+// basicAuthMiddleware guards the wrapped routes with HTTP Basic Auth when
+// the workspace setting enables it, comparing SHA-256 digests of the
+// supplied credentials with crypto/subtle to avoid timing leaks. It's
+// skipped for the gRPC gateway path, which authenticates via apiv2 instead,
+// and skipped entirely when the workspace setting is disabled, since Echo's
+// BasicAuthWithConfig 401s on any request without an Authorization header
+// before Validator ever runs.
+func (s *Server) basicAuthMiddleware() echo.MiddlewareFunc {
+	return middleware.BasicAuthWithConfig(middleware.BasicAuthConfig{
+		Skipper: func(c echo.Context) bool {
+			return grpcRequestSkipper(c) || !s.config.Load().basicAuth.enabled
+		},
+		Validator: func(username, password string, _ echo.Context) (bool, error) {
+			cfg := s.config.Load().basicAuth
+			if cfg == nil || !cfg.enabled {
+				return true, nil
+			}
+			usernameDigest := sha256.Sum256([]byte(username))
+			passwordDigest := sha256.Sum256([]byte(password))
+			usernameMatch := subtle.ConstantTimeCompare(usernameDigest[:], cfg.usernameDigest[:]) == 1
+			passwordMatch := subtle.ConstantTimeCompare(passwordDigest[:], cfg.passwordDigest[:]) == 1
+			return usernameMatch && passwordMatch, nil
+		},
+	})
 }
 
-func (s *Server) Shutdown(ctx context.Context) {
+// Shutdown stops accepting new connections on both the Echo and gRPC
+// listeners, drains in-flight HTTP requests and RPCs concurrently (bounded
+// by the same 10s deadline on both), waits for background workers started
+// via trackBackground to finish, and only then closes the store. Errors are
+// aggregated and returned rather than printed, so callers can surface
+// shutdown failures instead of losing them to stdout. Both listeners came
+// from the same cmux-multiplexed socket (see listener.go), so whichever
+// side's GracefulStop/Shutdown loses the race to close it first makes the
+// other observe a "closed" error on its own listener; isClosedErr filters
+// that out so a normal graceful shutdown doesn't surface a spurious error.
+func (s *Server) Shutdown(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	// Shutdown echo server.
-	if err := s.e.Shutdown(ctx); err != nil {
-		fmt.Printf("failed to shutdown server, error: %v\n", err)
-	}
+	// A plain errgroup.Group (not WithContext) so one shutdown path failing
+	// doesn't cancel the shared context and cut the other's grace period
+	// short; both run against the same 10s deadline independently.
+	var g errgroup.Group
+	g.Go(func() error {
+		if err := s.e.Shutdown(ctx); err != nil && !isClosedErr(err) {
+			return errors.Wrap(err, "failed to shut down http server")
+		}
+		return nil
+	})
+	g.Go(func() error {
+		stopped := make(chan struct{})
+		go func() {
+			s.apiV2Service.GetGRPCServer().GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			s.apiV2Service.GetGRPCServer().Stop()
+			return errors.Wrap(ctx.Err(), "grpc graceful stop deadline exceeded")
+		}
+	})
+	shutdownErr := g.Wait()
 
-	// Close database connection.
-	if err := s.Store.Close(); err != nil {
-		fmt.Printf("failed to close database, error: %v\n", err)
+	// e.Shutdown/GracefulStop above only stop the gRPC and HTTP
+	// sub-listeners cmux split out of the root socket; the root socket
+	// itself is still being accepted on by lns.mux.Serve() in one of Run's
+	// errgroup goroutines until we close it here, which is what lets that
+	// goroutine return and Run's g.Wait() unblock.
+	if s.lns != nil {
+		s.lns.mux.Close()
 	}
 
-	fmt.Printf("server stopped properly\n")
+	// Background workers (license subscription refresh, metrics sampling,
+	// and any resource-service work registered via trackBackground) get a
+	// chance to observe cancellation before the store closes under them.
+	s.cancelBackground()
+	s.bg.Wait()
+
+	closeErr := errors.Wrap(s.Store.Close(), "failed to close database")
+	return stderrors.Join(shutdownErr, closeErr)
 }
 
 func (s *Server) GetEcho() *echo.Echo {
@@ -171,24 +382,209 @@ func grpcRequestSkipper(c echo.Context) bool {
 	return strings.HasPrefix(c.Request().URL.Path, "/slash.api.v2.")
 }
 
+// requestLoggerMiddleware replaces the old JSON echo logger with structured
+// logging on the existing internal/log zap logger, mirrored to slog. It
+// assigns a request ID (taken from X-Request-ID, or generated if the client
+// didn't send one), echoes it back on the response, and injects it into the
+// request context so RegisterGateway forwards it as gRPC metadata that
+// grpcmw.UnaryServerInterceptor/StreamServerInterceptor log under on the
+// apiv2 side.
+func (s *Server) requestLoggerMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			requestID := requestIDFromHeader(c.Request().Header.Get(requestIDHeader))
+			c.Response().Header().Set(requestIDHeader, requestID)
+			c.SetRequest(c.Request().WithContext(contextWithRequestID(c.Request().Context(), requestID)))
+
+			err := next(c)
+
+			fields := []zap.Field{
+				zap.String("method", c.Request().Method),
+				zap.String("path", c.Path()),
+				zap.Int("status", c.Response().Status),
+				zap.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+				zap.String("real_ip", c.RealIP()),
+				zap.String("user_agent", c.Request().UserAgent()),
+				zap.String("request_id", requestID),
+			}
+			if userID := userIDFromEcho(c); userID != 0 {
+				fields = append(fields, zap.Int32("user_id", userID))
+			}
+			if err != nil {
+				fields = append(fields, zap.Error(err))
+			}
+			log.Info("http request", fields...)
+
+			slog.Info("http request",
+				"method", c.Request().Method,
+				"path", c.Path(),
+				"status", c.Response().Status,
+				"latency_ms", float64(time.Since(start).Microseconds())/1000,
+				"real_ip", c.RealIP(),
+				"user_agent", c.Request().UserAgent(),
+				"request_id", requestID,
+				"user_id", userIDFromEcho(c),
+			)
+
+			return err
+		}
+	}
+}
+
+// userIDFromEcho reads the authenticated user ID stashed on the echo context
+// by the api v1 session middleware, returning 0 for unauthenticated requests.
+func userIDFromEcho(c echo.Context) int32 {
+	if userID, ok := c.Get("user-id").(int32); ok {
+		return userID
+	}
+	return 0
+}
+
+// reloadableRateLimiterStore lazily rebuilds the underlying in-memory rate
+// limiter store whenever ReloadConfig swaps in new rate-limit rules, so the
+// rest of the middleware chain never needs to know a reload happened.
+type reloadableRateLimiterStore struct {
+	s *Server
+
+	mu    sync.Mutex
+	cfg   middleware.RateLimiterMemoryStoreConfig
+	store middleware.RateLimiterStore
+}
+
+func (r *reloadableRateLimiterStore) Allow(identifier string) (bool, error) {
+	cfg := r.s.config.Load().rateLimit
+
+	r.mu.Lock()
+	if r.store == nil || r.cfg != cfg {
+		r.cfg = cfg
+		r.store = middleware.NewRateLimiterMemoryStoreWithConfig(cfg)
+	}
+	store := r.store
+	r.mu.Unlock()
+
+	return store.Allow(identifier)
+}
+
+// getSecretSessionName returns the cookie-signing session secret, storing it
+// encrypted at rest under the workspace's keyset rather than as a plaintext
+// UUID. A secret written before encryption-at-rest existed is transparently
+// migrated in place on first read.
 func (s *Server) getSecretSessionName(ctx context.Context) (string, error) {
+	ks, err := s.loadKeyset()
+	if err != nil {
+		return "", err
+	}
+
 	secretSessionSetting, err := s.Store.GetWorkspaceSetting(ctx, &store.FindWorkspaceSetting{
 		Key: storepb.WorkspaceSettingKey_WORKSPACE_SETTING_SECRET_SESSION,
 	})
 	if err != nil {
 		return "", err
 	}
+
 	if secretSessionSetting == nil {
 		tempSecret := uuid.New().String()
-		secretSessionSetting, err = s.Store.UpsertWorkspaceSetting(ctx, &storepb.WorkspaceSetting{
+		sealed, err := ks.Seal(tempSecret)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to seal session secret")
+		}
+		if _, err := s.Store.UpsertWorkspaceSetting(ctx, &storepb.WorkspaceSetting{
+			Key: storepb.WorkspaceSettingKey_WORKSPACE_SETTING_SECRET_SESSION,
+			Value: &storepb.WorkspaceSetting_SecretSession{
+				SecretSession: sealed,
+			},
+		}); err != nil {
+			return "", err
+		}
+		return tempSecret, nil
+	}
+
+	stored := secretSessionSetting.GetSecretSession()
+	if !keyset.IsSealed(stored) {
+		sealed, err := ks.Seal(stored)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to seal session secret")
+		}
+		if _, err := s.Store.UpsertWorkspaceSetting(ctx, &storepb.WorkspaceSetting{
 			Key: storepb.WorkspaceSettingKey_WORKSPACE_SETTING_SECRET_SESSION,
 			Value: &storepb.WorkspaceSetting_SecretSession{
-				SecretSession: tempSecret,
+				SecretSession: sealed,
 			},
-		})
+		}); err != nil {
+			return "", err
+		}
+		return stored, nil
+	}
+
+	plaintext, err := ks.Unseal(stored)
+	if err != nil {
+		return "", err
+	}
+
+	// A value sealed under a key that's since been rotated out of primary is
+	// re-sealed under the current primary on this read, so a retired key's
+	// exposure shrinks over time instead of protecting rows forever.
+	if keyID, ok := keyset.KeyID(stored); ok && keyID != ks.PrimaryKeyID {
+		resealed, err := ks.Seal(plaintext)
 		if err != nil {
+			return "", errors.Wrap(err, "failed to reseal session secret")
+		}
+		if _, err := s.Store.UpsertWorkspaceSetting(ctx, &storepb.WorkspaceSetting{
+			Key: storepb.WorkspaceSettingKey_WORKSPACE_SETTING_SECRET_SESSION,
+			Value: &storepb.WorkspaceSetting_SecretSession{
+				SecretSession: resealed,
+			},
+		}); err != nil {
 			return "", err
 		}
 	}
-	return secretSessionSetting.GetSecretSession(), nil
+
+	return plaintext, nil
+}
+
+// RotateKeyset generates a new primary key and persists it, retiring the
+// previous primary to unseal-only. Values already sealed under the
+// retired key are re-sealed under the new primary the next time
+// getSecretSessionName reads them. Triggered by SIGUSR1; see
+// watchRotateSignal in listener.go.
+func (s *Server) RotateKeyset(ctx context.Context) error {
+	ks, err := s.loadKeyset()
+	if err != nil {
+		return err
+	}
+	if err := ks.Rotate(); err != nil {
+		return errors.Wrap(err, "failed to rotate keyset")
+	}
+	if err := ks.Save(s.Profile.KeysetPath); err != nil {
+		return errors.Wrap(err, "failed to persist rotated keyset")
+	}
+	return s.ReloadConfig(ctx)
+}
+
+// loadKeyset reads the keyset from profile.KeysetPath, bootstrapping and
+// persisting a new one on first run. profile.KeysetPath is required: a
+// bootstrapped keyset that can't be persisted would re-bootstrap (and so
+// re-seal every workspace secret under a new, unrecoverable key) on every
+// restart, so we fail fast here instead of surfacing keyset.Save's generic
+// "path is empty" error deeper in the call stack.
+func (s *Server) loadKeyset() (*keyset.Keyset, error) {
+	if s.Profile.KeysetPath == "" {
+		return nil, errors.New("profile.KeysetPath is required to seal workspace secrets at rest")
+	}
+	ks, err := keyset.Load(s.Profile.KeysetPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load keyset")
+	}
+	if ks == nil {
+		ks, err = keyset.New()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to bootstrap keyset")
+		}
+		if err := ks.Save(s.Profile.KeysetPath); err != nil {
+			return nil, errors.Wrap(err, "failed to persist keyset")
+		}
+	}
+	return ks, nil
 }
@@ -0,0 +1,111 @@
+// Package grpcmw holds the gRPC server interceptors shared between the
+// server package (which assigns request IDs and serves the HTTP surface)
+// and api/v2 (which constructs the grpc.Server these interceptors chain
+// onto). It exists as its own leaf package specifically so api/v2 can
+// import it without creating an import cycle back through server, which
+// already imports api/v2 to register the gateway.
+package grpcmw
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourselfhosted/slash/internal/log"
+)
+
+var (
+	grpcRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "slash_grpc_requests_total",
+		Help: "Total number of gRPC requests, by method and status code.",
+	}, []string{"method", "code"})
+
+	grpcRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "slash_grpc_request_duration_seconds",
+		Help:    "gRPC request latency in seconds, by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// RequestIDMetadataKey is the outgoing/incoming gRPC metadata key the HTTP
+// gateway uses to forward the request ID assigned by server's request
+// logger, and that UnaryServerInterceptor/StreamServerInterceptor read back
+// here on the apiv2 side.
+const RequestIDMetadataKey = "x-request-id"
+
+// RequestIDFromIncomingContext recovers the request ID forwarded by the
+// HTTP gateway, generating a fresh one for callers that reach the gRPC
+// server directly without going through the gateway.
+func RequestIDFromIncomingContext(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if vals := md.Get(RequestIDMetadataKey); len(vals) > 0 && vals[0] != "" {
+			return vals[0]
+		}
+	}
+	return uuid.NewString()
+}
+
+// UnaryServerInterceptor logs each unary RPC under the same request ID the
+// HTTP logger assigned, so an operator can grep one ID across both surfaces.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestID := RequestIDFromIncomingContext(ctx)
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		log.Info("grpc request",
+			zap.String("request_id", requestID),
+			zap.String("method", info.FullMethod),
+			zap.Duration("latency", time.Since(start)),
+			zap.Error(err),
+		)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		requestID := RequestIDFromIncomingContext(ss.Context())
+		start := time.Now()
+		err := handler(srv, ss)
+		log.Info("grpc stream",
+			zap.String("request_id", requestID),
+			zap.String("method", info.FullMethod),
+			zap.Duration("latency", time.Since(start)),
+			zap.Error(err),
+		)
+		return err
+	}
+}
+
+// MetricsUnaryServerInterceptor records RED metrics for unary RPCs. It's
+// meant to be chained alongside UnaryServerInterceptor when apiv2 constructs
+// its grpc.Server, the same way the HTTP side layers requestLoggerMiddleware
+// and httpMetricsMiddleware independently.
+func MetricsUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		grpcRequestsTotal.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+		grpcRequestDuration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+		return resp, err
+	}
+}
+
+// MetricsStreamServerInterceptor is the streaming counterpart of MetricsUnaryServerInterceptor.
+func MetricsStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		grpcRequestsTotal.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+		grpcRequestDuration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
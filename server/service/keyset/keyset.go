@@ -0,0 +1,192 @@
+// Package keyset manages the JSON keyset used to seal workspace secrets
+// (currently just the cookie-signing session secret) at rest, so a rotated
+// or compromised key can be retired without touching the data it protects.
+package keyset
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Key is a single AES-256-GCM key in a Keyset.
+type Key struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Value     []byte    `json:"value"`
+}
+
+// Keyset is a JSON-serializable set of AES-GCM keys with a designated
+// primary used for sealing new values. Every key stays available for
+// unsealing, so rotating the primary doesn't invalidate data sealed under
+// an older one.
+type Keyset struct {
+	PrimaryKeyID string `json:"primaryKeyId"`
+	Keys         []Key  `json:"keys"`
+}
+
+// Load reads a keyset from path. A missing file is not an error: callers
+// should fall back to New to bootstrap one.
+func Load(path string) (*Keyset, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to read keyset file")
+	}
+	ks := &Keyset{}
+	if err := json.Unmarshal(data, ks); err != nil {
+		return nil, errors.Wrap(err, "failed to parse keyset file")
+	}
+	return ks, nil
+}
+
+// New generates a fresh keyset with a single primary key.
+func New() (*Keyset, error) {
+	key, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	return &Keyset{PrimaryKeyID: key.ID, Keys: []Key{*key}}, nil
+}
+
+// Rotate adds a new key and promotes it to primary, leaving older keys in
+// place so values already sealed under them can still be unsealed.
+func (k *Keyset) Rotate() error {
+	key, err := newKey()
+	if err != nil {
+		return err
+	}
+	k.Keys = append(k.Keys, *key)
+	k.PrimaryKeyID = key.ID
+	return nil
+}
+
+// Save writes the keyset to path as indented JSON, readable only by the
+// owner since it contains raw AES keys.
+func (k *Keyset) Save(path string) error {
+	if path == "" {
+		return errors.New("keyset path is empty")
+	}
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal keyset")
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Seal encrypts plaintext with the primary key. The returned value is
+// prefixed with the key ID so Unseal can find the right key even after
+// Rotate changes the primary.
+func (k *Keyset) Seal(plaintext string) (string, error) {
+	primary, ok := k.key(k.PrimaryKeyID)
+	if !ok {
+		return "", errors.New("keyset has no primary key")
+	}
+	gcm, err := newGCM(primary.Value)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.Wrap(err, "failed to generate nonce")
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return primary.ID + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Unseal decrypts a value produced by Seal.
+func (k *Keyset) Unseal(sealed string) (string, error) {
+	id, encoded, ok := strings.Cut(sealed, ":")
+	if !ok {
+		return "", errors.New("malformed sealed value")
+	}
+	key, ok := k.key(id)
+	if !ok {
+		return "", errors.Errorf("unknown keyset key id %q", id)
+	}
+	gcm, err := newGCM(key.Value)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode sealed value")
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("sealed value is too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decrypt sealed value")
+	}
+	return string(plaintext), nil
+}
+
+// IsSealed reports whether value looks like something Seal produced, as
+// opposed to a plaintext secret written before encryption-at-rest existed.
+func IsSealed(value string) bool {
+	id, _, ok := strings.Cut(value, ":")
+	return ok && id != ""
+}
+
+// KeyID extracts the key ID a sealed value was produced under, without
+// decrypting it, so a caller can tell whether a value needs re-sealing under
+// a new primary key after Rotate.
+func KeyID(sealed string) (string, bool) {
+	id, _, ok := strings.Cut(sealed, ":")
+	if !ok || id == "" {
+		return "", false
+	}
+	return id, true
+}
+
+func (k *Keyset) key(id string) (Key, bool) {
+	for _, key := range k.Keys {
+		if key.ID == id {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+func newKey() (*Key, error) {
+	value := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, value); err != nil {
+		return nil, errors.Wrap(err, "failed to generate key")
+	}
+	id := make([]byte, 8)
+	if _, err := io.ReadFull(rand.Reader, id); err != nil {
+		return nil, errors.Wrap(err, "failed to generate key id")
+	}
+	return &Key{
+		ID:        base64.RawURLEncoding.EncodeToString(id),
+		CreatedAt: time.Now(),
+		Value:     value,
+	}, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create gcm")
+	}
+	return gcm, nil
+}
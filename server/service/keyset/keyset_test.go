@@ -0,0 +1,66 @@
+package keyset
+
+import "testing"
+
+func TestSealUnsealRoundTrip(t *testing.T) {
+	ks, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	sealed, err := ks.Seal("hello world")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if !IsSealed(sealed) {
+		t.Fatalf("IsSealed(%q) = false, want true", sealed)
+	}
+
+	plaintext, err := ks.Unseal(sealed)
+	if err != nil {
+		t.Fatalf("Unseal() error = %v", err)
+	}
+	if plaintext != "hello world" {
+		t.Errorf("Unseal() = %q, want %q", plaintext, "hello world")
+	}
+}
+
+func TestRotateKeepsOldKeysUnsealable(t *testing.T) {
+	ks, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	sealedUnderOldKey, err := ks.Seal("pre-rotation secret")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	oldPrimaryID := ks.PrimaryKeyID
+
+	if err := ks.Rotate(); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if ks.PrimaryKeyID == oldPrimaryID {
+		t.Fatalf("PrimaryKeyID unchanged after Rotate()")
+	}
+
+	plaintext, err := ks.Unseal(sealedUnderOldKey)
+	if err != nil {
+		t.Fatalf("Unseal() of pre-rotation value error = %v", err)
+	}
+	if plaintext != "pre-rotation secret" {
+		t.Errorf("Unseal() = %q, want %q", plaintext, "pre-rotation secret")
+	}
+
+	sealedUnderNewKey, err := ks.Seal("post-rotation secret")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	keyID, ok := KeyID(sealedUnderNewKey)
+	if !ok {
+		t.Fatalf("KeyID(%q) ok = false, want true", sealedUnderNewKey)
+	}
+	if keyID != ks.PrimaryKeyID {
+		t.Errorf("KeyID() = %q, want %q", keyID, ks.PrimaryKeyID)
+	}
+}
@@ -0,0 +1,151 @@
+// Package store provides typed access to the application's persistent
+// data: workspace settings and shortcuts.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	storepb "github.com/yourselfhosted/slash/proto/gen/store"
+)
+
+// Store wraps the underlying database connection.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore wraps an already-opened database connection.
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// GetDB returns the underlying *sql.DB, e.g. for connection-pool metrics.
+func (s *Store) GetDB() *sql.DB {
+	return s.db
+}
+
+// Close closes the underlying database connection.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// FindWorkspaceSetting filters GetWorkspaceSetting by key.
+type FindWorkspaceSetting struct {
+	Key storepb.WorkspaceSettingKey
+}
+
+// GetWorkspaceSetting returns the workspace setting for find.Key, or nil if
+// it hasn't been set yet.
+func (s *Store) GetWorkspaceSetting(ctx context.Context, find *FindWorkspaceSetting) (*storepb.WorkspaceSetting, error) {
+	var raw string
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM workspace_setting WHERE key = ?`, int32(find.Key)).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get workspace setting")
+	}
+
+	setting := &storepb.WorkspaceSetting{Key: find.Key}
+	switch find.Key {
+	case storepb.WorkspaceSettingKey_WORKSPACE_SETTING_SECRET_SESSION:
+		var value string
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal secret session setting")
+		}
+		setting.Value = &storepb.WorkspaceSetting_SecretSession{SecretSession: value}
+	case storepb.WorkspaceSettingKey_WORKSPACE_SETTING_LICENSE:
+		value := &storepb.License{}
+		if err := json.Unmarshal([]byte(raw), value); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal license setting")
+		}
+		setting.Value = &storepb.WorkspaceSetting_License{License: value}
+	case storepb.WorkspaceSettingKey_WORKSPACE_SETTING_CORS_ORIGINS:
+		value := &storepb.CorsOrigins{}
+		if err := json.Unmarshal([]byte(raw), value); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal cors origins setting")
+		}
+		setting.Value = &storepb.WorkspaceSetting_CorsOrigins{CorsOrigins: value}
+	case storepb.WorkspaceSettingKey_WORKSPACE_SETTING_RATE_LIMIT:
+		value := &storepb.RateLimit{}
+		if err := json.Unmarshal([]byte(raw), value); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal rate limit setting")
+		}
+		setting.Value = &storepb.WorkspaceSetting_RateLimit{RateLimit: value}
+	case storepb.WorkspaceSettingKey_WORKSPACE_SETTING_BASIC_AUTH:
+		value := &storepb.BasicAuth{}
+		if err := json.Unmarshal([]byte(raw), value); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal basic auth setting")
+		}
+		setting.Value = &storepb.WorkspaceSetting_BasicAuth{BasicAuth: value}
+	default:
+		return nil, errors.Errorf("unsupported workspace setting key %v", find.Key)
+	}
+	return setting, nil
+}
+
+// UpsertWorkspaceSetting inserts or updates the workspace setting row keyed
+// by setting.Key.
+func (s *Store) UpsertWorkspaceSetting(ctx context.Context, setting *storepb.WorkspaceSetting) (*storepb.WorkspaceSetting, error) {
+	var raw []byte
+	var err error
+	switch v := setting.Value.(type) {
+	case *storepb.WorkspaceSetting_SecretSession:
+		raw, err = json.Marshal(v.SecretSession)
+	case *storepb.WorkspaceSetting_License:
+		raw, err = json.Marshal(v.License)
+	case *storepb.WorkspaceSetting_CorsOrigins:
+		raw, err = json.Marshal(v.CorsOrigins)
+	case *storepb.WorkspaceSetting_RateLimit:
+		raw, err = json.Marshal(v.RateLimit)
+	case *storepb.WorkspaceSetting_BasicAuth:
+		raw, err = json.Marshal(v.BasicAuth)
+	default:
+		return nil, errors.Errorf("unsupported workspace setting value %T", setting.Value)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal workspace setting")
+	}
+
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO workspace_setting (key, value) VALUES (?, ?)
+		 ON CONFLICT(key) DO UPDATE SET value = excluded.value`,
+		int32(setting.Key), string(raw),
+	); err != nil {
+		return nil, errors.Wrap(err, "failed to upsert workspace setting")
+	}
+	return setting, nil
+}
+
+// FindShortcut filters ListShortcuts. An empty FindShortcut selects every
+// shortcut.
+type FindShortcut struct{}
+
+// Shortcut is a single short-link row.
+type Shortcut struct {
+	ID   int32
+	Name string
+	Link string
+}
+
+// ListShortcuts returns every shortcut matching find.
+func (s *Store) ListShortcuts(ctx context.Context, find *FindShortcut) ([]*Shortcut, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, name, link FROM shortcut`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list shortcuts")
+	}
+	defer rows.Close()
+
+	var shortcuts []*Shortcut
+	for rows.Next() {
+		shortcut := &Shortcut{}
+		if err := rows.Scan(&shortcut.ID, &shortcut.Name, &shortcut.Link); err != nil {
+			return nil, errors.Wrap(err, "failed to scan shortcut")
+		}
+		shortcuts = append(shortcuts, shortcut)
+	}
+	return shortcuts, rows.Err()
+}